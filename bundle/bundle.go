@@ -3,22 +3,162 @@ package bundle
 import (
 	"archive/zip"
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
+	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/suborbital/appspec/bundle/cas"
 	"github.com/suborbital/appspec/tenant"
 )
 
+// ErrNotModified is returned by ReadURL when the remote bundle's ETag
+// matches the one passed via WithIfNoneMatch, meaning the caller's existing
+// copy is still current.
+var ErrNotModified = errors.New("bundle: remote bundle not modified")
+
 // Bundle represents a Runnable bundle.
 type Bundle struct {
-	filepath     string
 	TenantConfig *tenant.Config
-	staticFiles  map[string]bool
+	staticFiles  map[string]string // relative path -> content hash, or "" for a pre-manifest bundle that embeds the file directly under static/
+	store        cas.Store
+	tag          string
+	signature    *Signature
+
+	// ETag is populated by ReadURL from the remote server's ETag response
+	// header, if any, so it can be passed to a later ReadURL via
+	// WithIfNoneMatch to cheaply poll for changes.
+	ETag string
+
+	// zipFilesFn returns the bundle's zip directory on demand, along with an
+	// io.Closer to release any per-call resources it opened. It is how the
+	// bundle retains access to its underlying archive without assuming a
+	// local file path it can reopen (see Read, ReadFrom, and ReadURL).
+	zipFilesFn func() ([]*zip.File, io.Closer, error)
+
+	// closer, if set, is closed by Bundle.Close. It is only set when the
+	// bundle itself opened and is retaining a resource across calls (see
+	// KeepOpen).
+	closer io.Closer
+}
+
+// ReadOption configures optional behavior for Read, ReadFrom, and ReadURL.
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	keepOpen    bool
+	store       cas.Store
+	tag         string
+	httpClient  *http.Client
+	headers     http.Header
+	ifNoneMatch string
+	verify      *VerifyOptions
+}
+
+// KeepOpen instructs Read to retain the bundle's underlying *zip.ReadCloser
+// rather than closing it once the tenant config and wasm modules have been
+// loaded. This avoids re-opening the zip archive from disk on every
+// StaticFile or StaticFS call. Callers that use it must call Bundle.Close
+// when they're finished with the bundle.
+func KeepOpen() ReadOption {
+	return func(c *readConfig) {
+		c.keepOpen = true
+	}
+}
+
+// UseStore directs Read to resolve module and static file content through
+// the given Store, associating everything it loads with tag (for example a
+// tenant identifier and version). This allows a host that loads many tenant
+// bundles to share identical wasm modules or static assets, both in memory
+// and on disk, instead of decoding the same bytes out of every bundle that
+// contains them. If omitted, Read uses a cas.PassthroughStore and resolves
+// content directly from the bundle's own zip archive.
+func UseStore(store cas.Store, tag string) ReadOption {
+	return func(c *readConfig) {
+		c.store = store
+		c.tag = tag
+	}
+}
+
+// WithHTTPClient sets the *http.Client ReadURL uses to fetch the bundle,
+// instead of http.DefaultClient. Ignored by Read and ReadFrom.
+func WithHTTPClient(client *http.Client) ReadOption {
+	return func(c *readConfig) {
+		c.httpClient = client
+	}
+}
+
+// WithHeader adds a header, such as an Authorization header, to the requests
+// ReadURL uses to fetch the bundle. Ignored by Read and ReadFrom.
+func WithHeader(key, value string) ReadOption {
+	return func(c *readConfig) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+
+		c.headers.Add(key, value)
+	}
+}
+
+// WithIfNoneMatch sets the If-None-Match header ReadURL sends, so a remote
+// server can cheaply report that a previously-fetched bundle is unchanged by
+// responding 304 Not Modified; in that case ReadURL returns ErrNotModified.
+// Ignored by Read and ReadFrom.
+func WithIfNoneMatch(etag string) ReadOption {
+	return func(c *readConfig) {
+		c.ifNoneMatch = etag
+	}
+}
+
+// VerifyOptions configures bundle signature verification during Read,
+// ReadFrom, and ReadURL.
+type VerifyOptions struct {
+	// Keyring maps a signing key's id to the public key that should verify a
+	// signature claiming that id.
+	Keyring map[string]ed25519.PublicKey
+
+	// Required, if true, causes reading to fail with ErrSignatureInvalid
+	// when the bundle has no signature, or its signature doesn't verify
+	// against a key in Keyring.
+	Required bool
+}
+
+// WithVerification directs Read, ReadFrom, and ReadURL to check the bundle's
+// signature.json (if any) against opts.Keyring. If opts.Required is true, a
+// missing or invalid signature fails the read with ErrSignatureInvalid;
+// otherwise the result is only reflected in Bundle.Signature.
+func WithVerification(opts VerifyOptions) ReadOption {
+	return func(c *readConfig) {
+		c.verify = &opts
+	}
+}
+
+// Close releases any resources retained by the bundle, such as a zip reader
+// kept open via KeepOpen. It is a no-op if no such resources are held.
+func (b *Bundle) Close() error {
+	if b.closer != nil {
+		return b.closer.Close()
+	}
+
+	return nil
 }
 
 // StaticFile returns a static file from the bundle, if it exists.
@@ -26,23 +166,36 @@ func (b *Bundle) StaticFile(filePath string) ([]byte, error) {
 	// normalize in case the caller added `/` or `./` to the filename.
 	filePath = NormalizeStaticFilename(filePath)
 
-	if _, exists := b.staticFiles[filePath]; !exists {
+	hash, exists := b.staticFiles[filePath]
+	if !exists {
 		return nil, os.ErrNotExist
 	}
 
-	r, err := zip.OpenReader(b.filepath)
+	if hash != "" {
+		return b.contentByHash(hash, filePath)
+	}
+
+	return b.legacyStaticFile(filePath)
+}
+
+// legacyStaticFile reads a static file that was written before bundles
+// carried a manifest, i.e. one embedded directly at static/<path> in the zip.
+func (b *Bundle) legacyStaticFile(filePath string) ([]byte, error) {
+	files, closer, err := b.zipFiles()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to open bundle")
 	}
 
-	defer r.Close()
+	if closer != nil {
+		defer closer.Close()
+	}
 
 	// re-add the static/ prefix to ensure sandboxing to the static directory.
 	staticFilePath := ensurePrefix(filePath, "static/")
 
 	var contents []byte
 
-	for _, f := range r.File {
+	for _, f := range files {
 		if f.Name == staticFilePath {
 			file, err := f.Open()
 			if err != nil {
@@ -63,14 +216,381 @@ func (b *Bundle) StaticFile(filePath string) ([]byte, error) {
 	return contents, nil
 }
 
+// contentByHash resolves a manifest-addressed content entry through the
+// bundle's Store, populating the store from the bundle's own content/<hash>
+// zip entry the first time it's requested.
+func (b *Bundle) contentByHash(hash, filePath string) ([]byte, error) {
+	if err := validateContentHash(hash); err != nil {
+		return nil, errors.Wrapf(err, "invalid content hash for %s", filePath)
+	}
+
+	files, closer, err := b.zipFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open bundle")
+	}
+
+	if closer != nil {
+		defer closer.Close()
+	}
+
+	var zf *zip.File
+
+	for _, f := range files {
+		if f.Name == contentEntryName(hash) {
+			zf = f
+			break
+		}
+	}
+
+	rc, err := b.store.GetOrCreate(b.tag, hash, func(dst io.Writer) error {
+		if zf == nil {
+			return errors.Errorf("content for hash %s not present in bundle or store", hash)
+		}
+
+		file, err := zf.Open()
+		if err != nil {
+			return errors.Wrapf(err, "failed to open content %s", zf.Name)
+		}
+
+		defer file.Close()
+
+		contents, err := ioutil.ReadAll(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read content %s", zf.Name)
+		}
+
+		if got := contentHash(contents); got != hash {
+			return errors.Errorf("content for %s does not match its claimed hash %s (got %s)", filePath, hash, got)
+		}
+
+		_, err = dst.Write(contents)
+
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to GetOrCreate content for %s", filePath)
+	}
+
+	defer rc.Close()
+
+	contents, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ReadAll content for %s", filePath)
+	}
+
+	return contents, nil
+}
+
+// Signature returns the bundle's signature metadata and whether it was
+// signed at all, regardless of whether verification was requested via
+// WithVerification. Downstream schedulers can use this to log or enforce
+// which key signed a loaded tenant.
+func (b *Bundle) Signature() (*Signature, bool) {
+	return b.signature, b.signature != nil
+}
+
+// StaticFS returns an http.FileSystem backed by the bundle's static/ directory,
+// suitable for use with http.FileServer or any router that accepts an
+// http.FileSystem. Directory listings are synthesized from the bundle's
+// static file index, and paths outside static/ resolve to os.ErrNotExist.
+func (b *Bundle) StaticFS() http.FileSystem {
+	return &staticFS{bundle: b}
+}
+
+// zipFiles returns the list of files in the bundle's zip archive, along with
+// an io.Closer to release any per-call resources opened to read them. The
+// closer is nil when the bundle already retains its own open reader (see
+// KeepOpen, ReadFrom, and ReadURL).
+func (b *Bundle) zipFiles() ([]*zip.File, io.Closer, error) {
+	return b.zipFilesFn()
+}
+
+// staticFS implements http.FileSystem over a Bundle's static/ directory.
+type staticFS struct {
+	bundle *Bundle
+}
+
+func (fs *staticFS) Open(name string) (http.File, error) {
+	normalized := NormalizeStaticFilename(name)
+
+	if normalized != "" && normalized != "." {
+		if _, exists := fs.bundle.staticFiles[normalized]; exists {
+			contents, err := fs.bundle.StaticFile(normalized)
+			if err != nil {
+				return nil, err
+			}
+
+			return &staticHTTPFile{
+				info:   &staticFileInfo{name: path.Base(normalized), size: int64(len(contents)), mode: 0444},
+				reader: bytes.NewReader(contents),
+			}, nil
+		}
+	}
+
+	return fs.openDir(normalized)
+}
+
+func (fs *staticFS) openDir(dir string) (http.File, error) {
+	prefix := dir
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	entries := make([]os.FileInfo, 0)
+	seen := map[string]bool{}
+
+	for name := range fs.bundle.staticFiles {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(name, prefix)
+		if rest == "" {
+			continue
+		}
+
+		parts := strings.SplitN(rest, "/", 2)
+		entryName := parts[0]
+
+		if entryName == "" || seen[entryName] {
+			continue
+		}
+
+		seen[entryName] = true
+
+		if len(parts) > 1 {
+			entries = append(entries, &staticFileInfo{name: entryName, isDir: true, mode: os.ModeDir | 0555})
+		} else {
+			entries = append(entries, &staticFileInfo{name: entryName, mode: 0444})
+		}
+	}
+
+	if len(entries) == 0 && prefix != "" {
+		return nil, os.ErrNotExist
+	}
+
+	dirName := path.Base(dir)
+	if dirName == "" || dirName == "." || dirName == "/" {
+		dirName = "."
+	}
+
+	return &staticHTTPFile{
+		info:    &staticFileInfo{name: dirName, isDir: true, mode: os.ModeDir | 0555},
+		entries: entries,
+	}, nil
+}
+
+// staticFileInfo implements os.FileInfo for an entry in the bundle's static directory.
+type staticFileInfo struct {
+	name    string
+	size    int64
+	mode    os.FileMode
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi *staticFileInfo) Name() string       { return fi.name }
+func (fi *staticFileInfo) Size() int64        { return fi.size }
+func (fi *staticFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *staticFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *staticFileInfo) IsDir() bool        { return fi.isDir }
+func (fi *staticFileInfo) Sys() interface{}   { return nil }
+
+// staticHTTPFile implements http.File over a fully-read static file (using a
+// bytes.Reader to satisfy io.Seeker, which http.ServeContent requires for
+// range requests) or a synthesized directory listing.
+type staticHTTPFile struct {
+	info    *staticFileInfo
+	reader  *bytes.Reader
+	entries []os.FileInfo
+}
+
+func (f *staticHTTPFile) Close() error {
+	return nil
+}
+
+func (f *staticHTTPFile) Read(p []byte) (int, error) {
+	if f.reader == nil {
+		return 0, io.EOF
+	}
+
+	return f.reader.Read(p)
+}
+
+func (f *staticHTTPFile) Seek(offset int64, whence int) (int64, error) {
+	if f.reader == nil {
+		return 0, os.ErrInvalid
+	}
+
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *staticHTTPFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.info.isDir {
+		return nil, errors.New("not a directory")
+	}
+
+	if count <= 0 {
+		entries := f.entries
+		f.entries = nil
+
+		return entries, nil
+	}
+
+	if count > len(f.entries) {
+		count = len(f.entries)
+	}
+
+	entries := f.entries[:count]
+	f.entries = f.entries[count:]
+
+	return entries, nil
+}
+
+func (f *staticHTTPFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
+
+// manifestEntry records the logical name of a bundle entry (a `<module>.wasm`
+// or `static/<path>` name) alongside the sha256 digest of its contents.
+type manifestEntry struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// manifest is written into every bundle as manifest.json; it maps each
+// module and static file back to the content-addressed entry that holds its
+// bytes, so identical content can be stored (and deduplicated) once.
+type manifest struct {
+	Entries []manifestEntry `json:"entries"`
+}
+
+func contentHash(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+func contentEntryName(hash string) string {
+	return "content/" + hash
+}
+
+// validHashPattern matches a lowercase hex-encoded sha256 digest, the only
+// shape a content hash should ever take.
+var validHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// validateContentHash rejects anything that isn't a well-formed sha256
+// digest. Manifest entries come from manifest.json, which a bundle author
+// controls but a verifier doesn't, so entry.Hash is untrusted input before
+// it's used as a Store key - without this check a crafted manifest could use
+// it to smuggle path separators or ".." into a Store built on the filesystem.
+func validateContentHash(hash string) error {
+	if !validHashPattern.MatchString(hash) {
+		return errors.Errorf("invalid content hash %q: must be a lowercase hex-encoded sha256 digest", hash)
+	}
+
+	return nil
+}
+
+// Signature is the detached signature a bundle carries as signature.json. It
+// covers the canonical digest of the bundle's manifest plus tenant.yaml - the
+// sorted (name, sha256(contents)) pairs of every entry, including a synthetic
+// "tenant.yaml" entry - so any addition, removal, or modification of a
+// module, static file, or the tenant config itself invalidates it.
+type Signature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"keyId"`
+	Signature string    `json:"signature"` // base64-encoded
+	Digest    string    `json:"digest"`    // hex-encoded sha256
+	SignedAt  time.Time `json:"signedAt"`
+}
+
+// ErrSignatureInvalid is returned when bundle verification was required but
+// the bundle's signature is missing, malformed, or does not match its
+// contents.
+type ErrSignatureInvalid struct {
+	Reason string
+}
+
+func (e *ErrSignatureInvalid) Error() string {
+	return fmt.Sprintf("bundle: invalid signature: %s", e.Reason)
+}
+
+// tenantConfigEntryName is the synthetic manifest entry name under which
+// tenant.yaml's content hash is folded into canonicalDigest, so signing
+// covers the tenant config even though it lives outside the manifest itself.
+const tenantConfigEntryName = "tenant.yaml"
+
+// tenantConfigEntry returns the synthetic manifest entry that represents
+// tenant.yaml in a signature's digest.
+func tenantConfigEntry(tenantConfigBytes []byte) manifestEntry {
+	return manifestEntry{Name: tenantConfigEntryName, Hash: contentHash(tenantConfigBytes)}
+}
+
+// canonicalDigest computes the sha256 digest that a bundle's signature
+// covers: each entry's "name:hash" pair, sorted by name so the digest is
+// independent of manifest ordering.
+func canonicalDigest(entries []manifestEntry) []byte {
+	sorted := make([]manifestEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	buf := new(bytes.Buffer)
+	for _, entry := range sorted {
+		fmt.Fprintf(buf, "%s:%s\n", entry.Name, entry.Hash)
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+
+	return sum[:]
+}
+
+// WriteOption configures optional behavior for Write.
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	store     cas.Store
+	tag       string
+	signKeyID string
+	signKey   ed25519.PrivateKey
+}
+
+// WithStore directs Write to persist module and static file contents through
+// the given Store, keyed by their sha256 digest and associated with tag
+// (for example a tenant identifier and version), instead of the default
+// in-memory passthrough. A Store shared across multiple Write calls lets
+// identical wasm modules or static assets across tenant bundles be stored
+// once on disk.
+func WithStore(store cas.Store, tag string) WriteOption {
+	return func(c *writeConfig) {
+		c.store = store
+		c.tag = tag
+	}
+}
+
+// WithSignature directs Write to sign the bundle's manifest with key and
+// embed the result as signature.json, identified by keyID so a verifier
+// knows which public key to check it against.
+func WithSignature(keyID string, key ed25519.PrivateKey) WriteOption {
+	return func(c *writeConfig) {
+		c.signKeyID = keyID
+		c.signKey = key
+	}
+}
+
 // Write writes a runnable bundle
 // based loosely on https://golang.org/src/archive/zip/example_test.go
 // staticFiles should be a map of *relative* filepaths to their associated files, with or without the `static/` prefix.
-func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]os.File, targetPath string) error {
+func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]os.File, targetPath string, opts ...WriteOption) error {
 	if tenantConfigBytes == nil || len(tenantConfigBytes) == 0 {
 		return errors.New("tenant config must be provided")
 	}
 
+	cfg := &writeConfig{store: cas.PassthroughStore{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	// Create a buffer to write our archive to.
 	buf := new(bytes.Buffer)
 
@@ -82,6 +602,8 @@ func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]o
 		return errors.Wrap(err, "failed to writeTenantConfig")
 	}
 
+	ws := newWriteState(cfg, tenantConfigBytes)
+
 	// Add the Wasm modules to the archive.
 	for _, file := range modules {
 		if file.Name() == "tenant.yaml" || file.Name() == "tenant.yml" {
@@ -94,8 +616,8 @@ func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]o
 			return errors.Wrapf(err, "failed to read file %s", file.Name())
 		}
 
-		if err := writeFile(w, filepath.Base(file.Name()), contents); err != nil {
-			return errors.Wrap(err, "failed to writeFile into bundle")
+		if err := ws.addEntry(w, filepath.Base(file.Name()), contents); err != nil {
+			return err
 		}
 	}
 
@@ -106,12 +628,15 @@ func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]o
 			return errors.Wrapf(err, "failed to read file %s", file.Name())
 		}
 
-		fileName := ensurePrefix(path, "static/")
-		if err := writeFile(w, fileName, contents); err != nil {
-			return errors.Wrap(err, "failed to writeFile into bundle")
+		if err := ws.addEntry(w, ensurePrefix(path, "static/"), contents); err != nil {
+			return err
 		}
 	}
 
+	if err := ws.finish(w); err != nil {
+		return err
+	}
+
 	if err := w.Close(); err != nil {
 		return errors.Wrap(err, "failed to close bundle writer")
 	}
@@ -123,119 +648,950 @@ func Write(tenantConfigBytes []byte, modules []os.File, staticFiles map[string]o
 	return nil
 }
 
-func writeTenantConfig(w *zip.Writer, tenantConfigBytes []byte) error {
-	if err := writeFile(w, "tenant.yaml", tenantConfigBytes); err != nil {
-		return errors.Wrap(err, "failed to writeFile for tenant.yaml")
-	}
+// ModuleSource supplies a single wasm module's logical name and contents to
+// WriteStreaming.
+type ModuleSource struct {
+	Name   string
+	Reader io.Reader
+}
 
-	return nil
+// StaticSource streams static file entries one at a time, so WriteStreaming
+// can add a large asset tree to a bundle without holding every file open at
+// once, unlike Write's staticFiles map. Next returns io.EOF once exhausted.
+type StaticSource interface {
+	Next() (name string, r io.Reader, err error)
 }
 
-func writeFile(w *zip.Writer, name string, contents []byte) error {
-	f, err := w.Create(name)
-	if err != nil {
-		return errors.Wrap(err, "failed to add file to bundle")
+// WriteStreaming writes a runnable bundle like Write, but takes its modules
+// and static files as streams and writes directly to w instead of a target
+// path, so building a bundle from a large asset tree doesn't require holding
+// every os.File open simultaneously.
+func WriteStreaming(tenantConfigBytes []byte, modules []ModuleSource, static StaticSource, w io.Writer, opts ...WriteOption) error {
+	if tenantConfigBytes == nil || len(tenantConfigBytes) == 0 {
+		return errors.New("tenant config must be provided")
 	}
 
-	_, err = f.Write(contents)
-	if err != nil {
-		return errors.Wrap(err, "failed to write file into bundle")
+	cfg := &writeConfig{store: cas.PassthroughStore{}}
+	for _, opt := range opts {
+		opt(cfg)
 	}
 
-	return nil
-}
+	zw := zip.NewWriter(w)
 
-// Read reads a .wasm.zip file and returns the bundle of wasm modules
-// (suitable to be loaded into a wasmer instance).
-func Read(path string) (*Bundle, error) {
-	// Open a zip archive for reading.
-	r, err := zip.OpenReader(path)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open bundle")
+	if err := writeTenantConfig(zw, tenantConfigBytes); err != nil {
+		return errors.Wrap(err, "failed to writeTenantConfig")
 	}
 
-	defer r.Close()
+	ws := newWriteState(cfg, tenantConfigBytes)
 
-	bundle := &Bundle{
-		filepath:    path,
-		staticFiles: map[string]bool{},
+	for _, mod := range modules {
+		contents, err := ioutil.ReadAll(mod.Reader)
+		closeIfCloser(mod.Reader)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read module %s", mod.Name)
+		}
+
+		if err := ws.addEntry(zw, filepath.Base(mod.Name), contents); err != nil {
+			return err
+		}
 	}
 
-	// first, find the tenant config.
-	for _, f := range r.File {
-		if f.Name == "tenant.yaml" {
-			tenantConfig, err := readTenantConfig(f)
+	if static != nil {
+		for {
+			name, r, err := static.Next()
+			if err == io.EOF {
+				break
+			}
+
 			if err != nil {
-				return nil, errors.Wrap(err, "failed to readTenantConfig from bundle")
+				return errors.Wrap(err, "failed to read next static source entry")
 			}
 
-			bundle.TenantConfig = tenantConfig
-			continue
+			contents, err := ioutil.ReadAll(r)
+			closeIfCloser(r)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read static file %s", name)
+			}
+
+			if err := ws.addEntry(zw, ensurePrefix(name, "static/"), contents); err != nil {
+				return err
+			}
 		}
 	}
 
-	if bundle.TenantConfig == nil {
-		return nil, errors.New("bundle is missing tenant.yaml")
+	if err := ws.finish(zw); err != nil {
+		return err
 	}
 
-	// Iterate through the files in the archive.
-	for _, f := range r.File {
-		if f.Name == "tenant.yaml" {
-			// we already have a tenant config by now.
-			continue
-		} else if strings.HasPrefix(f.Name, "static/") {
-			// build up the list of available static files in the bundle for quick reference later.
-			filePath := strings.TrimPrefix(f.Name, "static/")
-			bundle.staticFiles[filePath] = true
-			continue
-		} else if !strings.HasSuffix(f.Name, ".wasm") {
-			continue
-		}
+	if err := zw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close bundle writer")
+	}
 
-		rc, err := f.Open()
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to open %s from bundle", f.Name)
-		}
+	return nil
+}
 
-		defer rc.Close()
+// CollectStaticDir walks root and returns a map suitable for Write's
+// staticFiles parameter, keyed by the slash-separated path of each file
+// relative to root. Symlinks that resolve outside root are skipped rather
+// than followed, so a bundle built from the result can't be tricked into
+// embedding files from elsewhere on disk. Symlinks that resolve to a
+// directory inside root are followed, with cycle detection, so the files
+// inside end up in the result under the symlink's own path, just as if it
+// were a real directory.
+func CollectStaticDir(root string) (map[string]os.File, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve %s", root)
+	}
 
-		wasmBytes, err := ioutil.ReadAll(rc)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to read %s from bundle", f.Name)
-		}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to EvalSymlinks for %s", root)
+	}
 
-		runnable := bundle.TenantConfig.FindModule(strings.TrimSuffix(f.Name, ".wasm"))
-		if runnable == nil {
-			return nil, fmt.Errorf("unable to find Runnable for module %s", f.Name)
-		}
+	files := map[string]os.File{}
 
-		runnable.WasmRef = tenant.NewWasmModuleRef(f.Name, runnable.FQMN, wasmBytes)
+	// symlink targets are compared in fully resolved form, so compare them
+	// against root's resolved path too.
+	if err := collectStaticDir(root, "", realRoot, map[string]bool{realRoot: true}, files); err != nil {
+		return nil, errors.Wrapf(err, "failed to WalkDir %s", root)
 	}
 
-	if bundle.TenantConfig == nil {
-		return nil, errors.New("bundle did not contain tenantConfig")
+	return files, nil
+}
+
+// collectStaticDir walks walkRoot - root itself on the initial call, or the
+// real directory a symlink resolves to on a recursive one - and records
+// every file it finds in files, keyed by its slash-separated path relative
+// to the original root: logicalPrefix (the path, relative to root, that led
+// here) joined with the file's path relative to walkRoot. absRoot is root's
+// resolved absolute path. visited tracks the directories already used as a
+// walk root - root itself and every symlink target followed - by their
+// resolved absolute path, so a symlink cycle can't recurse forever;
+// directories reached by the plain walk are not recorded in it.
+func collectStaticDir(walkRoot, logicalPrefix, absRoot string, visited map[string]bool, files map[string]os.File) error {
+	return filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(walkRoot, p)
+		if err != nil {
+			return errors.Wrapf(err, "failed to Rel %s", p)
+		}
+
+		logicalRel := rel
+		if logicalPrefix != "" {
+			logicalRel = filepath.Join(logicalPrefix, rel)
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if d.Type()&os.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(p)
+			if err != nil {
+				return errors.Wrapf(err, "failed to EvalSymlinks for %s", p)
+			}
+
+			relToRoot, err := filepath.Rel(absRoot, target)
+			if err != nil || relToRoot == ".." || strings.HasPrefix(relToRoot, ".."+string(filepath.Separator)) {
+				// escapes root; skip it rather than following it in.
+				return nil
+			}
+
+			info, err := os.Stat(target)
+			if err != nil {
+				return errors.Wrapf(err, "failed to Stat %s", target)
+			}
+
+			if info.IsDir() {
+				if visited[target] {
+					// already walked this real directory, through this or
+					// another symlink; following it again would recurse
+					// forever on a cycle.
+					return nil
+				}
+
+				visited[target] = true
+
+				return collectStaticDir(target, logicalRel, absRoot, visited, files)
+			}
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return errors.Wrapf(err, "failed to Open %s", p)
+		}
+
+		files[filepath.ToSlash(logicalRel)] = *f
+
+		return nil
+	})
+}
+
+// writeState accumulates the manifest and content-addressed entries shared
+// by Write and WriteStreaming.
+type writeState struct {
+	cfg               *writeConfig
+	man               *manifest
+	written           map[string]bool
+	tenantConfigBytes []byte
+}
+
+func newWriteState(cfg *writeConfig, tenantConfigBytes []byte) *writeState {
+	return &writeState{
+		cfg:               cfg,
+		man:               &manifest{},
+		written:           map[string]bool{},
+		tenantConfigBytes: tenantConfigBytes,
+	}
+}
+
+// addEntry records name in the manifest and, the first time its content hash
+// is seen, writes the content itself into w as a content/<hash> entry.
+func (ws *writeState) addEntry(w *zip.Writer, name string, contents []byte) error {
+	if err := validateEntryName(name); err != nil {
+		return err
+	}
+
+	hash := contentHash(contents)
+
+	rc, err := ws.cfg.store.GetOrCreate(ws.cfg.tag, hash, func(dst io.Writer) error {
+		_, err := dst.Write(contents)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to GetOrCreate content for %s", name)
+	}
+
+	defer rc.Close()
+
+	if !ws.written[hash] {
+		stored, err := ioutil.ReadAll(rc)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read stored content for %s", name)
+		}
+
+		if err := writeFile(w, contentEntryName(hash), stored); err != nil {
+			return errors.Wrapf(err, "failed to writeFile content for %s", name)
+		}
+
+		ws.written[hash] = true
+	}
+
+	ws.man.Entries = append(ws.man.Entries, manifestEntry{Name: name, Hash: hash})
+
+	return nil
+}
+
+// finish writes the accumulated manifest, and a signature over it if the
+// caller used WithSignature.
+func (ws *writeState) finish(w *zip.Writer) error {
+	manifestBytes, err := json.Marshal(ws.man)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal manifest")
+	}
+
+	if err := writeFile(w, "manifest.json", manifestBytes); err != nil {
+		return errors.Wrap(err, "failed to writeFile manifest")
+	}
+
+	if ws.cfg.signKey == nil {
+		return nil
+	}
+
+	digestEntries := append([]manifestEntry{tenantConfigEntry(ws.tenantConfigBytes)}, ws.man.Entries...)
+	digest := canonicalDigest(digestEntries)
+
+	sig := &Signature{
+		Algorithm: "ed25519",
+		KeyID:     ws.cfg.signKeyID,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(ws.cfg.signKey, digest)),
+		Digest:    hex.EncodeToString(digest),
+		SignedAt:  time.Now().UTC(),
+	}
+
+	sigBytes, err := json.Marshal(sig)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal signature")
+	}
+
+	if err := writeFile(w, "signature.json", sigBytes); err != nil {
+		return errors.Wrap(err, "failed to writeFile signature")
+	}
+
+	return nil
+}
+
+// validateEntryName rejects names that could escape the static/ or content/
+// directories when the bundle is later extracted (zip-slip).
+func validateEntryName(name string) error {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return errors.Errorf("invalid bundle entry name %q: absolute paths are not allowed", name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return errors.Errorf("invalid bundle entry name %q: .. segments are not allowed", name)
+		}
+	}
+
+	return nil
+}
+
+func writeTenantConfig(w *zip.Writer, tenantConfigBytes []byte) error {
+	if err := writeFile(w, "tenant.yaml", tenantConfigBytes); err != nil {
+		return errors.Wrap(err, "failed to writeFile for tenant.yaml")
+	}
+
+	return nil
+}
+
+func writeFile(w *zip.Writer, name string, contents []byte) error {
+	f, err := w.Create(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to add file to bundle")
+	}
+
+	_, err = f.Write(contents)
+	if err != nil {
+		return errors.Wrap(err, "failed to write file into bundle")
+	}
+
+	return nil
+}
+
+// Read reads a .wasm.zip file and returns the bundle of wasm modules
+// (suitable to be loaded into a wasmer instance).
+func Read(path string, opts ...ReadOption) (*Bundle, error) {
+	cfg := &readConfig{store: cas.PassthroughStore{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// Open a zip archive for reading.
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open bundle")
+	}
+
+	bundle, err := newBundle(r.File, cfg)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	if cfg.keepOpen {
+		// retain this reader for the life of the bundle instead of reopening
+		// the file on every StaticFile/StaticFS call.
+		bundle.zipFilesFn = func() ([]*zip.File, io.Closer, error) {
+			return r.File, nil, nil
+		}
+
+		bundle.closer = r
+	} else {
+		r.Close()
+
+		bundle.zipFilesFn = func() ([]*zip.File, io.Closer, error) {
+			rc, err := zip.OpenReader(path)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to open bundle")
+			}
+
+			return rc.File, rc, nil
+		}
+	}
+
+	return bundle, nil
+}
+
+// ReadFrom reads a bundle from r, which must support random-access reads of
+// size bytes - for example an in-memory buffer, an *os.File, or any other
+// io.ReaderAt a caller already has open. Unlike Read, the resulting Bundle
+// keeps referencing r directly rather than assuming a local file path it can
+// reopen, so it works for bundles sourced from S3, GCS, or anywhere else
+// that doesn't have one.
+func ReadFrom(r io.ReaderAt, size int64, opts ...ReadOption) (*Bundle, error) {
+	cfg := &readConfig{store: cas.PassthroughStore{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open bundle")
+	}
+
+	bundle, err := newBundle(zr.File, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.zipFilesFn = func() ([]*zip.File, io.Closer, error) {
+		return zr.File, nil, nil
 	}
 
 	return bundle, nil
 }
 
-func readTenantConfig(f *zip.File) (*tenant.Config, error) {
+// ReadURL fetches a .wasm.zip bundle over HTTP(S) and reads it. Static file
+// content is fetched lazily via Range requests rather than all at once, so a
+// large bundle can be read without downloading assets that are never
+// requested. Pass WithHTTPClient to use a custom *http.Client, WithHeader to
+// attach auth headers, and WithIfNoneMatch to cheaply poll for an updated
+// bundle.
+func ReadURL(ctx context.Context, url string, opts ...ReadOption) (*Bundle, error) {
+	cfg := &readConfig{store: cas.PassthroughStore{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	client := cfg.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	head, err := headBundle(ctx, client, url, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if head.notModified {
+		return nil, ErrNotModified
+	}
+
+	reader := &httpRangeReaderAt{ctx: ctx, client: client, url: url, headers: cfg.headers}
+	if head.fullBody != nil {
+		// the size check below already fetched the whole bundle (a
+		// Range-GET fallback against a server that ignores Range), so
+		// seed the reader's cache instead of fetching it all again.
+		reader.fullBody = head.fullBody
+	}
+
+	zr, err := zip.NewReader(reader, head.size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open remote bundle")
+	}
+
+	bundle, err := newBundle(zr.File, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle.ETag = head.etag
+	bundle.zipFilesFn = func() ([]*zip.File, io.Closer, error) {
+		return zr.File, nil, nil
+	}
+
+	return bundle, nil
+}
+
+// bundleHead describes what ReadURL needs to know before it can start
+// issuing Range requests against a remote bundle.
+type bundleHead struct {
+	size        int64
+	etag        string
+	notModified bool
+
+	// fullBody is set when determining size required fetching the whole
+	// bundle anyway (a Range-GET against a server that ignores Range), so
+	// the caller can seed httpRangeReaderAt's cache instead of re-fetching.
+	fullBody []byte
+}
+
+// headBundle determines a remote bundle's size and ETag before issuing
+// Range requests against it, and reports whether the server indicated the
+// bundle is unchanged from cfg.ifNoneMatch. It prefers a HEAD request, but
+// presigned S3/GCS URLs are commonly signed for GET only and reject HEAD
+// with an auth error, so a failed HEAD falls back to a ranged GET.
+func headBundle(ctx context.Context, client *http.Client, url string, cfg *readConfig) (*bundleHead, error) {
+	head, err := headBundleHEAD(ctx, client, url, cfg)
+	if err == nil {
+		return head, nil
+	}
+
+	return headBundleRangeGET(ctx, client, url, cfg)
+}
+
+func headBundleHEAD(ctx context.Context, client *http.Client, url string, cfg *readConfig) (*bundleHead, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build HEAD request")
+	}
+
+	applyHeaders(req, cfg)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to HEAD bundle")
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &bundleHead{etag: resp.Header.Get("ETag"), notModified: true}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status HEADing bundle: %s", resp.Status)
+	}
+
+	return &bundleHead{size: resp.ContentLength, etag: resp.Header.Get("ETag")}, nil
+}
+
+// headBundleRangeGET determines a bundle's size by requesting a single byte
+// via Range instead of HEAD, for servers that reject HEAD against a
+// GET-signed URL. If the server ignores the Range header and returns the
+// whole body with a 200, that body is kept so the caller doesn't have to
+// fetch it again.
+func headBundleRangeGET(ctx context.Context, client *http.Client, url string, cfg *readConfig) (*bundleHead, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build GET request")
+	}
+
+	applyHeaders(req, cfg)
+	req.Header.Set("Range", "bytes=0-0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to GET bundle")
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return &bundleHead{etag: resp.Header.Get("ETag"), notModified: true}, nil
+	case http.StatusPartialContent:
+		total, err := parseContentRangeTotal(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &bundleHead{size: total, etag: resp.Header.Get("ETag")}, nil
+	case http.StatusOK:
+		// the server ignored our Range header and sent the whole body.
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read bundle body")
+		}
+
+		return &bundleHead{size: int64(len(body)), etag: resp.Header.Get("ETag"), fullBody: body}, nil
+	default:
+		return nil, fmt.Errorf("unexpected status GETing bundle range: %s", resp.Status)
+	}
+}
+
+// parseContentRangeTotal extracts the total size from a Content-Range
+// response header of the form "bytes 0-0/12345".
+func parseContentRangeTotal(contentRange string) (int64, error) {
+	_, totalStr, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return 0, fmt.Errorf("malformed Content-Range header: %q", contentRange)
+	}
+
+	total, err := strconv.ParseInt(totalStr, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "malformed Content-Range total: %q", contentRange)
+	}
+
+	return total, nil
+}
+
+func applyHeaders(req *http.Request, cfg *readConfig) {
+	for key, values := range cfg.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if cfg.ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", cfg.ifNoneMatch)
+	}
+}
+
+// httpRangeReaderAt is an io.ReaderAt that issues byte-range GET requests
+// against a remote URL, so a bundle fetched via ReadURL can defer
+// downloading static file contents until they're actually requested. Some
+// servers ignore the Range header and return the whole body with a 200
+// instead of a 206; rather than silently reading from the wrong offset, the
+// first such response is buffered in full and every ReadAt is served from it.
+type httpRangeReaderAt struct {
+	ctx     context.Context
+	client  *http.Client
+	url     string
+	headers http.Header
+
+	mu       sync.Mutex
+	fullBody []byte
+}
+
+func (h *httpRangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if body, ok := h.cachedFullBody(); ok {
+		return readAtOffset(body, p, off)
+	}
+
+	req, err := http.NewRequestWithContext(h.ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	for key, values := range h.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return io.ReadFull(resp.Body, p)
+	case http.StatusOK:
+		// the server ignored our Range header; it's sending the whole body
+		// from the start, not bytes starting at off. Buffer it once and
+		// serve every read, including this one, out of memory from here on.
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		h.mu.Lock()
+		h.fullBody = body
+		h.mu.Unlock()
+
+		return readAtOffset(body, p, off)
+	default:
+		return 0, fmt.Errorf("unexpected status fetching bundle range: %s", resp.Status)
+	}
+}
+
+func (h *httpRangeReaderAt) cachedFullBody() ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.fullBody, h.fullBody != nil
+}
+
+// readAtOffset copies body[off:] into p, following io.ReaderAt's contract of
+// returning io.EOF alongside a short read when body is exhausted.
+func readAtOffset(body, p []byte, off int64) (int, error) {
+	if off >= int64(len(body)) {
+		return 0, io.EOF
+	}
+
+	n := copy(p, body[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// newBundle parses the tenant config, manifest, and wasm modules out of
+// files and returns the resulting Bundle. It does not populate zipFilesFn;
+// callers are responsible for wiring that up based on where files came from.
+func newBundle(files []*zip.File, cfg *readConfig) (*Bundle, error) {
+	bundle := &Bundle{
+		staticFiles: map[string]string{},
+		store:       cfg.store,
+		tag:         cfg.tag,
+	}
+
+	var man *manifest
+
+	var sig *Signature
+
+	var tenantConfigBytes []byte
+
+	contentFiles := map[string]*zip.File{}
+
+	// first, find the tenant config, manifest, and signature (if any).
+	for _, f := range files {
+		if f.Name == "tenant.yaml" {
+			raw, tenantConfig, err := readTenantConfig(f)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to readTenantConfig from bundle")
+			}
+
+			tenantConfigBytes = raw
+			bundle.TenantConfig = tenantConfig
+		} else if f.Name == "manifest.json" {
+			parsed, err := readManifest(f)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to readManifest from bundle")
+			}
+
+			man = parsed
+		} else if f.Name == "signature.json" {
+			parsed, err := readSignature(f)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to readSignature from bundle")
+			}
+
+			sig = parsed
+		} else if strings.HasPrefix(f.Name, "content/") {
+			contentFiles[strings.TrimPrefix(f.Name, "content/")] = f
+		}
+	}
+
+	if bundle.TenantConfig == nil {
+		return nil, errors.New("bundle is missing tenant.yaml")
+	}
+
+	if err := verifySignature(sig, man, tenantConfigBytes, cfg.verify); err != nil {
+		return nil, err
+	}
+
+	bundle.signature = sig
+
+	if man != nil {
+		if err := bundle.loadManifest(man, contentFiles); err != nil {
+			return nil, err
+		}
+	} else if err := bundle.loadLegacy(files); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// verifySignature checks sig against the canonical digest of man and
+// tenantConfigBytes using opts, if opts was requested via WithVerification. It
+// returns ErrSignatureInvalid only when opts.Required is true and something
+// about the signature doesn't check out; otherwise verification failures are
+// silently ignored so that Bundle.Signature still reflects what was found.
+func verifySignature(sig *Signature, man *manifest, tenantConfigBytes []byte, opts *VerifyOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	fail := func(reason string) error {
+		if !opts.Required {
+			return nil
+		}
+
+		return &ErrSignatureInvalid{Reason: reason}
+	}
+
+	if sig == nil || man == nil {
+		return fail("bundle is not signed")
+	}
+
+	digestEntries := append([]manifestEntry{tenantConfigEntry(tenantConfigBytes)}, man.Entries...)
+	expectedDigest := canonicalDigest(digestEntries)
+	if hex.EncodeToString(expectedDigest) != sig.Digest {
+		return fail("signature digest does not match bundle contents")
+	}
+
+	pub, ok := opts.Keyring[sig.KeyID]
+	if !ok {
+		return fail(fmt.Sprintf("unknown signing key %q", sig.KeyID))
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return fail("signature is not valid base64")
+	}
+
+	if !ed25519.Verify(pub, expectedDigest, sigBytes) {
+		return fail("signature does not verify against a known key")
+	}
+
+	return nil
+}
+
+// loadManifest populates the bundle's wasm modules and static file index from
+// a manifest, resolving each entry's content through the bundle's Store.
+func (b *Bundle) loadManifest(man *manifest, contentFiles map[string]*zip.File) error {
+	for _, entry := range man.Entries {
+		if err := validateContentHash(entry.Hash); err != nil {
+			return errors.Wrapf(err, "invalid manifest entry %s", entry.Name)
+		}
+
+		if strings.HasPrefix(entry.Name, "static/") {
+			b.staticFiles[strings.TrimPrefix(entry.Name, "static/")] = entry.Hash
+			continue
+		}
+
+		if !strings.HasSuffix(entry.Name, ".wasm") {
+			continue
+		}
+
+		zf := contentFiles[entry.Hash]
+
+		rc, err := b.store.GetOrCreate(b.tag, entry.Hash, func(dst io.Writer) error {
+			if zf == nil {
+				return errors.Errorf("content for hash %s not present in bundle or store", entry.Hash)
+			}
+
+			file, err := zf.Open()
+			if err != nil {
+				return errors.Wrapf(err, "failed to open content %s", zf.Name)
+			}
+
+			defer file.Close()
+
+			contents, err := ioutil.ReadAll(file)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read content %s", zf.Name)
+			}
+
+			// entry.Hash comes from manifest.json, which this bundle's author
+			// controls; recomputing it here stops a mismatched manifest from
+			// poisoning the shared Store under another tenant's hash.
+			if got := contentHash(contents); got != entry.Hash {
+				return errors.Errorf("content for %s does not match its claimed hash %s (got %s)", entry.Name, entry.Hash, got)
+			}
+
+			_, err = dst.Write(contents)
+
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to GetOrCreate content for %s", entry.Name)
+		}
+
+		wasmBytes, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read content for %s", entry.Name)
+		}
+
+		runnable := b.TenantConfig.FindModule(strings.TrimSuffix(entry.Name, ".wasm"))
+		if runnable == nil {
+			return fmt.Errorf("unable to find Runnable for module %s", entry.Name)
+		}
+
+		runnable.WasmRef = tenant.NewWasmModuleRef(entry.Name, runnable.FQMN, wasmBytes)
+	}
+
+	return nil
+}
+
+// loadLegacy populates the bundle's wasm modules and static file index from a
+// bundle written before manifests existed, where wasm modules and static
+// files are embedded directly in the zip under their own names.
+func (b *Bundle) loadLegacy(files []*zip.File) error {
+	for _, f := range files {
+		if f.Name == "tenant.yaml" {
+			continue
+		} else if strings.HasPrefix(f.Name, "static/") {
+			filePath := strings.TrimPrefix(f.Name, "static/")
+			b.staticFiles[filePath] = ""
+			continue
+		} else if !strings.HasSuffix(f.Name, ".wasm") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s from bundle", f.Name)
+		}
+
+		wasmBytes, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s from bundle", f.Name)
+		}
+
+		runnable := b.TenantConfig.FindModule(strings.TrimSuffix(f.Name, ".wasm"))
+		if runnable == nil {
+			return fmt.Errorf("unable to find Runnable for module %s", f.Name)
+		}
+
+		runnable.WasmRef = tenant.NewWasmModuleRef(f.Name, runnable.FQMN, wasmBytes)
+	}
+
+	return nil
+}
+
+// readTenantConfig returns both the raw tenant.yaml bytes, needed to verify
+// the bundle's signature, and the config parsed from them.
+func readTenantConfig(f *zip.File) ([]byte, *tenant.Config, error) {
 	file, err := f.Open()
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to open %s from bundle", f.Name)
+		return nil, nil, errors.Wrapf(err, "failed to open %s from bundle", f.Name)
 	}
 
+	defer file.Close()
+
 	tenantConfigBytes, err := ioutil.ReadAll(file)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read %s from bundle", f.Name)
+		return nil, nil, errors.Wrapf(err, "failed to read %s from bundle", f.Name)
 	}
 
 	d := &tenant.Config{}
 	if err := d.Unmarshal(tenantConfigBytes); err != nil {
-		return nil, errors.Wrap(err, "failed to Unmarshal tenant config")
+		return nil, nil, errors.Wrap(err, "failed to Unmarshal tenant config")
+	}
+
+	return tenantConfigBytes, d, nil
+}
+
+func readManifest(f *zip.File) (*manifest, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s from bundle", f.Name)
 	}
 
-	return d, nil
+	defer file.Close()
+
+	manifestBytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s from bundle", f.Name)
+	}
+
+	man := &manifest{}
+	if err := json.Unmarshal(manifestBytes, man); err != nil {
+		return nil, errors.Wrap(err, "failed to Unmarshal manifest")
+	}
+
+	return man, nil
+}
+
+func readSignature(f *zip.File) (*Signature, error) {
+	file, err := f.Open()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s from bundle", f.Name)
+	}
+
+	defer file.Close()
+
+	sigBytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s from bundle", f.Name)
+	}
+
+	sig := &Signature{}
+	if err := json.Unmarshal(sigBytes, sig); err != nil {
+		return nil, errors.Wrap(err, "failed to Unmarshal signature")
+	}
+
+	return sig, nil
+}
+
+// closeIfCloser closes r if it implements io.Closer, so a StaticSource or
+// ModuleSource that opens files lazily doesn't leak file descriptors once
+// WriteStreaming is done reading from them.
+func closeIfCloser(r io.Reader) {
+	if c, ok := r.(io.Closer); ok {
+		c.Close()
+	}
 }
 
 func ensurePrefix(val, prefix string) string {
@@ -0,0 +1,58 @@
+// Package cas provides a pluggable content-addressable storage abstraction
+// used by the bundle package to deduplicate wasm modules and static assets
+// across multiple tenant bundles.
+package cas
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// Store is a content-addressable store. Content is addressed by item, an
+// opaque key that callers derive from the content itself (typically a
+// sha256 digest), while tag groups a set of items under a logical label
+// (typically a tenant bundle version) for bookkeeping and garbage
+// collection.
+type Store interface {
+	// GetOrCreate returns a reader for the content addressed by item,
+	// recording that tag references it. If the item does not already exist
+	// in the store, create is invoked to populate it first.
+	GetOrCreate(tag, item string, create func(io.Writer) error) (io.ReadCloser, error)
+
+	// Tags returns every tag currently tracked by the store.
+	Tags() ([]string, error)
+
+	// Remove deletes every item referenced only by tag, and drops tag's
+	// association with any items that remain referenced elsewhere.
+	Remove(tag string) error
+}
+
+// PassthroughStore is a Store that does not persist anything; GetOrCreate
+// always invokes create and hands back its output directly. It exists so
+// callers that don't need cross-bundle deduplication can still satisfy the
+// Store interface, preserving bundle's original self-contained behavior.
+type PassthroughStore struct{}
+
+// GetOrCreate implements Store.
+func (PassthroughStore) GetOrCreate(tag, item string, create func(io.Writer) error) (io.ReadCloser, error) {
+	buf := new(bytes.Buffer)
+
+	if err := create(buf); err != nil {
+		return nil, errors.Wrap(err, "failed to create content")
+	}
+
+	return ioutil.NopCloser(buf), nil
+}
+
+// Tags implements Store.
+func (PassthroughStore) Tags() ([]string, error) {
+	return nil, nil
+}
+
+// Remove implements Store.
+func (PassthroughStore) Remove(tag string) error {
+	return nil
+}
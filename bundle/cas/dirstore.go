@@ -0,0 +1,225 @@
+package cas
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DirStore is a filesystem-backed Store. Content lives under
+// <base>/content/<item>, written via a temp file and renamed into place so
+// concurrent readers never observe a partial write. Which tags reference a
+// given item is tracked with empty marker files under
+// <base>/tags/<tag>/<item>, which Remove uses to decide whether an item's
+// content can be safely deleted.
+type DirStore struct {
+	base string
+	mu   sync.Mutex
+}
+
+// NewDirStore creates a DirStore rooted at base, creating it if necessary.
+func NewDirStore(base string) (*DirStore, error) {
+	if err := os.MkdirAll(filepath.Join(base, "content"), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to MkdirAll content dir")
+	}
+
+	if err := os.MkdirAll(filepath.Join(base, "tags"), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to MkdirAll tags dir")
+	}
+
+	return &DirStore{base: base}, nil
+}
+
+func (s *DirStore) contentPath(item string) string {
+	return filepath.Join(s.base, "content", item)
+}
+
+func (s *DirStore) tagDir(tag string) string {
+	return filepath.Join(s.base, "tags", tag)
+}
+
+func (s *DirStore) tagPath(tag, item string) string {
+	return filepath.Join(s.tagDir(tag), item)
+}
+
+// pathSafe rejects a tag or item that could escape the directory it's
+// joined into - callers are expected to derive these from content hashes,
+// but DirStore can't assume that holds, since a hand-crafted manifest.json
+// could hand it anything.
+func pathSafe(name string) error {
+	if name == "" {
+		return errors.New("must not be empty")
+	}
+
+	if strings.ContainsAny(name, `/\`) {
+		return errors.Errorf("%q must not contain path separators", name)
+	}
+
+	if name == "." || name == ".." {
+		return errors.Errorf("%q must not be a relative path segment", name)
+	}
+
+	return nil
+}
+
+// GetOrCreate implements Store.
+func (s *DirStore) GetOrCreate(tag, item string, create func(io.Writer) error) (io.ReadCloser, error) {
+	if err := pathSafe(tag); err != nil {
+		return nil, errors.Wrapf(err, "invalid tag %q", tag)
+	}
+
+	if err := pathSafe(item); err != nil {
+		return nil, errors.Wrapf(err, "invalid item %q", item)
+	}
+
+	s.mu.Lock()
+
+	if err := s.ensureContent(item, create); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	if err := s.recordTag(tag, item); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	s.mu.Unlock()
+
+	f, err := os.Open(s.contentPath(item))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open content")
+	}
+
+	return f, nil
+}
+
+// ensureContent must be called with s.mu held.
+func (s *DirStore) ensureContent(item string, create func(io.Writer) error) error {
+	if _, err := os.Stat(s.contentPath(item)); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to Stat content")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Join(s.base, "content"), "tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temp file")
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := create(tmp); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to create content")
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close temp file")
+	}
+
+	if err := os.Rename(tmp.Name(), s.contentPath(item)); err != nil {
+		return errors.Wrap(err, "failed to rename content into place")
+	}
+
+	return nil
+}
+
+// recordTag must be called with s.mu held.
+func (s *DirStore) recordTag(tag, item string) error {
+	if err := os.MkdirAll(s.tagDir(tag), 0755); err != nil {
+		return errors.Wrap(err, "failed to MkdirAll tag dir")
+	}
+
+	if err := ioutil.WriteFile(s.tagPath(tag, item), nil, 0644); err != nil {
+		return errors.Wrap(err, "failed to record tag reference")
+	}
+
+	return nil
+}
+
+// Tags implements Store.
+func (s *DirStore) Tags() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := ioutil.ReadDir(filepath.Join(s.base, "tags"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to ReadDir tags")
+	}
+
+	tags := make([]string, 0, len(entries))
+
+	for _, e := range entries {
+		if e.IsDir() {
+			tags = append(tags, e.Name())
+		}
+	}
+
+	return tags, nil
+}
+
+// Remove implements Store. Any item only referenced by tag is deleted from
+// disk; items still referenced by another tag are left alone.
+func (s *DirStore) Remove(tag string) error {
+	if err := pathSafe(tag); err != nil {
+		return errors.Wrapf(err, "invalid tag %q", tag)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := ioutil.ReadDir(s.tagDir(tag))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to ReadDir tag")
+	}
+
+	for _, item := range items {
+		referenced, err := s.referencedByOtherTag(tag, item.Name())
+		if err != nil {
+			return err
+		}
+
+		if !referenced {
+			if err := os.Remove(s.contentPath(item.Name())); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "failed to remove content")
+			}
+		}
+	}
+
+	if err := os.RemoveAll(s.tagDir(tag)); err != nil {
+		return errors.Wrap(err, "failed to RemoveAll tag dir")
+	}
+
+	return nil
+}
+
+func (s *DirStore) referencedByOtherTag(excludeTag, item string) (bool, error) {
+	tagDirs, err := ioutil.ReadDir(filepath.Join(s.base, "tags"))
+	if err != nil {
+		return false, errors.Wrap(err, "failed to ReadDir tags")
+	}
+
+	for _, t := range tagDirs {
+		if !t.IsDir() || t.Name() == excludeTag {
+			continue
+		}
+
+		if _, err := os.Stat(s.tagPath(t.Name(), item)); err == nil {
+			return true, nil
+		} else if !os.IsNotExist(err) {
+			return false, errors.Wrap(err, "failed to Stat tag reference")
+		}
+	}
+
+	return false, nil
+}
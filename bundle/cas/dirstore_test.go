@@ -0,0 +1,95 @@
+package cas_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle/cas"
+)
+
+func TestDirStoreRejectsPathTraversal(t *testing.T) {
+	base := t.TempDir()
+
+	store, err := cas.NewDirStore(base)
+	if err != nil {
+		t.Fatalf("NewDirStore: %s", err)
+	}
+
+	cases := []string{"../../evil", "a/../../evil", "/etc/passwd", "..", ""}
+
+	for _, item := range cases {
+		_, err := store.GetOrCreate("tenant-a", item, func(w io.Writer) error {
+			_, err := w.Write([]byte("pwned"))
+			return err
+		})
+
+		if err == nil {
+			t.Errorf("GetOrCreate(%q) should have been rejected", item)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(base), "evil")); err == nil {
+		t.Fatal("content escaped the store's base directory")
+	}
+}
+
+func TestDirStoreSharesContentAcrossTags(t *testing.T) {
+	base := t.TempDir()
+
+	store, err := cas.NewDirStore(base)
+	if err != nil {
+		t.Fatalf("NewDirStore: %s", err)
+	}
+
+	contents := []byte("hello")
+	item := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e7304336" // opaque item key; DirStore doesn't care what scheme produced it
+
+	create := func(w io.Writer) error {
+		_, err := w.Write(contents)
+		return err
+	}
+
+	rc1, err := store.GetOrCreate("tenant-a", item, create)
+	if err != nil {
+		t.Fatalf("GetOrCreate for tenant-a: %s", err)
+	}
+
+	got1, err := io.ReadAll(rc1)
+	rc1.Close()
+	if err != nil || string(got1) != string(contents) {
+		t.Fatalf("unexpected content for tenant-a: %q, err=%v", got1, err)
+	}
+
+	// tenant-b requests the same item without ever supplying contents again;
+	// create is only invoked the first time an item is written.
+	rc2, err := store.GetOrCreate("tenant-b", item, func(w io.Writer) error {
+		t.Fatal("create should not be invoked for an item that already exists")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrCreate for tenant-b: %s", err)
+	}
+
+	got2, err := io.ReadAll(rc2)
+	rc2.Close()
+	if err != nil || string(got2) != string(contents) {
+		t.Fatalf("unexpected content for tenant-b: %q, err=%v", got2, err)
+	}
+
+	if err := store.Remove("tenant-a"); err != nil {
+		t.Fatalf("Remove tenant-a: %s", err)
+	}
+
+	// tenant-b still references the item, so it must survive tenant-a's removal.
+	rc3, err := store.GetOrCreate("tenant-b", item, func(w io.Writer) error {
+		t.Fatal("create should not be invoked; tenant-b still references this item")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrCreate for tenant-b after Remove(tenant-a): %s", err)
+	}
+
+	rc3.Close()
+}
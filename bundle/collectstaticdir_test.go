@@ -0,0 +1,79 @@
+package bundle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+func TestCollectStaticDirFollowsNonEscapingSymlinkedDir(t *testing.T) {
+	root := t.TempDir()
+
+	realDir := filepath.Join(root, "real")
+	if err := os.Mkdir(realDir, 0755); err != nil {
+		t.Fatalf("Mkdir: %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(realDir, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	if err := os.Symlink(realDir, filepath.Join(root, "linked")); err != nil {
+		t.Fatalf("Symlink: %s", err)
+	}
+
+	files, err := bundle.CollectStaticDir(root)
+	if err != nil {
+		t.Fatalf("CollectStaticDir: %s", err)
+	}
+
+	f, ok := files["linked/a.txt"]
+	if !ok {
+		t.Fatalf("expected files to contain linked/a.txt, got %v", keysOf(files))
+	}
+
+	contents := make([]byte, 5)
+	if _, err := f.Read(contents); err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+
+	if string(contents) != "hello" {
+		t.Fatalf("unexpected content: %q", contents)
+	}
+
+	if _, ok := files["real/a.txt"]; !ok {
+		t.Fatalf("expected files to also contain real/a.txt via the direct walk, got %v", keysOf(files))
+	}
+}
+
+func TestCollectStaticDirSkipsSelfReferentialSymlink(t *testing.T) {
+	root := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	if err := os.Symlink(root, filepath.Join(root, "self")); err != nil {
+		t.Fatalf("Symlink: %s", err)
+	}
+
+	files, err := bundle.CollectStaticDir(root)
+	if err != nil {
+		t.Fatalf("CollectStaticDir: %s", err)
+	}
+
+	if _, ok := files["a.txt"]; !ok {
+		t.Fatalf("expected files to contain a.txt, got %v", keysOf(files))
+	}
+}
+
+func keysOf(files map[string]os.File) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,69 @@
+package bundle_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+// TestReadURLFallsBackToRangeGETWhenHEADRejected simulates a presigned
+// GET-only URL (as commonly produced by S3/GCS) that rejects HEAD with a 403
+// but serves Range GETs correctly, reproducing the method-signing constraint
+// ReadURL needs to work around.
+func TestReadURLFallsBackToRangeGETWhenHEADRejected(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "b.wasm.zip")
+
+	staticContent := []byte("hello from a static asset served over a GET-only presigned URL")
+
+	assetPath := filepath.Join(dir, "asset.txt")
+	if err := os.WriteFile(assetPath, staticContent, 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	assetFile, err := os.Open(assetPath)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer assetFile.Close()
+
+	if err := bundle.Write([]byte("name: original\n"), nil, map[string]os.File{"asset.txt": *assetFile}, target); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			http.Error(w, "forbidden: URL not signed for HEAD", http.StatusForbidden)
+			return
+		}
+
+		http.ServeContent(w, r, "b.wasm.zip", time.Time{}, bytes.NewReader(data))
+	}))
+	defer srv.Close()
+
+	b, err := bundle.ReadURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("ReadURL: %s", err)
+	}
+
+	got, err := b.StaticFile("asset.txt")
+	if err != nil {
+		t.Fatalf("StaticFile: %s", err)
+	}
+
+	if string(got) != string(staticContent) {
+		t.Fatalf("static content corrupted: got %q, want %q", got, staticContent)
+	}
+}
@@ -0,0 +1,74 @@
+package bundle_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+// TestReadURLAgainstRangeIgnoringServer exercises ReadURL against a server
+// that always responds 200 with the full body, ignoring the Range header
+// ReadURL's lazy reader sends - reproducing a server-side behavior that
+// previously caused ReadAt to silently read from the wrong offset.
+func TestReadURLAgainstRangeIgnoringServer(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "b.wasm.zip")
+
+	staticContent := []byte("hello from a static asset that spans more than one read")
+
+	assetPath := filepath.Join(dir, "asset.txt")
+	if err := os.WriteFile(assetPath, staticContent, 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	assetFile, err := os.Open(assetPath)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer assetFile.Close()
+
+	if err := bundle.Write([]byte("name: original\n"), nil, map[string]os.File{"asset.txt": *assetFile}, target); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// ignore any Range header and always serve the whole body with 200,
+		// as a plain static file server with no Range support would.
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	b, err := bundle.ReadURL(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("ReadURL: %s", err)
+	}
+
+	got, err := b.StaticFile("asset.txt")
+	if err != nil {
+		t.Fatalf("StaticFile: %s", err)
+	}
+
+	if !bytes.Equal(got, staticContent) {
+		t.Fatalf("static content corrupted: got %q, want %q", got, staticContent)
+	}
+}
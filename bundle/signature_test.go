@@ -0,0 +1,108 @@
+package bundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+func TestSignatureVerifiesUntamperedBundle(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %s", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "b.wasm.zip")
+
+	if err := bundle.Write([]byte("name: original\n"), nil, nil, target, bundle.WithSignature("k1", priv)); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	if _, err := bundle.Read(target, bundle.WithVerification(bundle.VerifyOptions{
+		Keyring:  map[string]ed25519.PublicKey{"k1": pub},
+		Required: true,
+	})); err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+}
+
+func TestSignatureRejectsTamperedTenantConfig(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %s", err)
+	}
+
+	original := filepath.Join(t.TempDir(), "b.wasm.zip")
+
+	if err := bundle.Write([]byte("name: original\n"), nil, nil, original, bundle.WithSignature("k1", priv)); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	tampered := filepath.Join(filepath.Dir(original), "tampered.wasm.zip")
+	if err := rewriteZipEntry(original, tampered, "tenant.yaml", []byte("name: tampered\n")); err != nil {
+		t.Fatalf("rewriteZipEntry: %s", err)
+	}
+
+	_, err = bundle.Read(tampered, bundle.WithVerification(bundle.VerifyOptions{
+		Keyring:  map[string]ed25519.PublicKey{"k1": pub},
+		Required: true,
+	}))
+
+	if _, ok := err.(*bundle.ErrSignatureInvalid); !ok {
+		t.Fatalf("expected ErrSignatureInvalid for a bundle with a tampered tenant.yaml, got %v", err)
+	}
+}
+
+// rewriteZipEntry copies the zip at src to dst, replacing entry's contents
+// with replacement and leaving every other entry untouched.
+func rewriteZipEntry(src, dst, entry string, replacement []byte) error {
+	raw, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			rc.Close()
+			return err
+		}
+
+		if f.Name == entry {
+			_, err = w.Write(replacement)
+		} else {
+			_, err = io.Copy(w, rc)
+		}
+
+		rc.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, buf.Bytes(), 0644)
+}
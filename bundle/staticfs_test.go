@@ -0,0 +1,109 @@
+package bundle_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+// TestStaticFSServesRangeRequestsAndDirectoryListings builds a bundle with a
+// nested and a top-level static file, then drives b.StaticFS() through a real
+// http.FileServer to exercise both Range-request handling (via io.Seeker) and
+// directory-listing generation (via Readdir).
+func TestStaticFSServesRangeRequestsAndDirectoryListings(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "b.wasm.zip")
+
+	topContent := []byte("hello from the top level")
+	nestedContent := []byte("hello from a nested asset")
+
+	topPath := filepath.Join(dir, "top.txt")
+	if err := os.WriteFile(topPath, topContent, 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	nestedPath := filepath.Join(dir, "nested.txt")
+	if err := os.WriteFile(nestedPath, nestedContent, 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+
+	topFile, err := os.Open(topPath)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer topFile.Close()
+
+	nestedFile, err := os.Open(nestedPath)
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer nestedFile.Close()
+
+	staticFiles := map[string]os.File{
+		"top.txt":           *topFile,
+		"assets/nested.txt": *nestedFile,
+	}
+
+	if err := bundle.Write([]byte("name: original\n"), nil, staticFiles, target); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	b, err := bundle.Read(target)
+	if err != nil {
+		t.Fatalf("Read: %s", err)
+	}
+	defer b.Close()
+
+	srv := httptest.NewServer(http.FileServer(b.StaticFS()))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/top.txt", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %s", err)
+	}
+	req.Header.Set("Range", "bytes=6-10")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("expected 206 Partial Content, got %d", resp.StatusCode)
+	}
+
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+
+	if want := topContent[6:11]; string(got) != string(want) {
+		t.Fatalf("unexpected range content: got %q, want %q", got, want)
+	}
+
+	listResp, err := http.Get(srv.URL + "/assets/")
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	defer listResp.Body.Close()
+
+	if listResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for directory listing, got %d", listResp.StatusCode)
+	}
+
+	listing, err := io.ReadAll(listResp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+
+	if !strings.Contains(string(listing), "nested.txt") {
+		t.Fatalf("expected directory listing to mention nested.txt, got %q", listing)
+	}
+}
@@ -0,0 +1,59 @@
+package bundle_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/suborbital/appspec/bundle"
+)
+
+// countingCloser tracks whether Close was called, standing in for a lazily
+// opened os.File that WriteStreaming shouldn't leak.
+type countingCloser struct {
+	io.Reader
+	closed bool
+}
+
+func (c *countingCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+type singleStaticSource struct {
+	name string
+	r    io.Reader
+	done bool
+}
+
+func (s *singleStaticSource) Next() (string, io.Reader, error) {
+	if s.done {
+		return "", nil, io.EOF
+	}
+
+	s.done = true
+
+	return s.name, s.r, nil
+}
+
+func TestWriteStreamingClosesReaders(t *testing.T) {
+	modReader := &countingCloser{Reader: bytes.NewReader([]byte("module bytes"))}
+	staticReader := &countingCloser{Reader: bytes.NewReader([]byte("static bytes"))}
+
+	buf := new(bytes.Buffer)
+
+	modules := []bundle.ModuleSource{{Name: "one.wasm", Reader: modReader}}
+	static := &singleStaticSource{name: "asset.txt", r: staticReader}
+
+	if err := bundle.WriteStreaming([]byte("name: original\n"), modules, static, buf); err != nil {
+		t.Fatalf("WriteStreaming: %s", err)
+	}
+
+	if !modReader.closed {
+		t.Error("module reader was not closed")
+	}
+
+	if !staticReader.closed {
+		t.Error("static file reader was not closed")
+	}
+}